@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// AnomalyClass 描述一次周期间隔相对于历史数据的异常程度
+type AnomalyClass string
+
+const (
+	AnomalyNormal  AnomalyClass = "normal"  // 正常波动范围内
+	AnomalyShort   AnomalyClass = "short"   // 明显偏短，可能存在点滴出血被误记为新周期
+	AnomalyLong    AnomalyClass = "long"    // 明显偏长，可能是跳过的周期
+	AnomalyExtreme AnomalyClass = "extreme" // 极端偏长，可能是漏记月经（如怀孕期闭经）
+)
+
+// AnomalyEntry 记录一次周期间隔（两次月经之间）的异常分析结果
+type AnomalyEntry struct {
+	Index             int          `json:"index"`                        // 间隔序号，从 1 开始
+	PrevDate          time.Time    `json:"prev_date"`                    // 间隔起点日期
+	NextDate          time.Time    `json:"next_date"`                    // 间隔终点日期
+	Gap               int          `json:"gap_days"`                     // 间隔天数
+	Class             AnomalyClass `json:"class"`                        // 异常分类
+	ZScore            float64      `json:"z_score"`                      // 相对 MAD 的 z-score
+	Imputed           bool         `json:"imputed"`                      // 是否已插入合成边界
+	ImputedBoundaries []time.Time  `json:"imputed_boundaries,omitempty"` // 插入的合成周期边界
+}
+
+// AnomalyReport 是一次完整的异常检测报告
+type AnomalyReport struct {
+	Median  float64        `json:"median"`
+	MAD     float64        `json:"mad"`
+	K       float64        `json:"k"`
+	Entries []AnomalyEntry `json:"entries"`
+}
+
+// buildAnomalyReport 基于排序后的月经日期，用中位数/MAD 做稳健的异常检测，
+// 取代此前 "gap < tolerance || gap > 35 即丢弃" 的硬性过滤
+func buildAnomalyReport(dates []time.Time, k float64, impute bool) AnomalyReport {
+	gaps := make([]int, 0, len(dates)-1)
+	for i := 1; i < len(dates); i++ {
+		gaps = append(gaps, daysBetween(dates[i-1], dates[i]))
+	}
+
+	med := median(gaps)
+	mad := medianAbsoluteDeviation(gaps, med)
+
+	entries := make([]AnomalyEntry, 0, len(gaps))
+	for i, gap := range gaps {
+		entry := AnomalyEntry{
+			Index:    i + 1,
+			PrevDate: dates[i],
+			NextDate: dates[i+1],
+			Gap:      gap,
+			Class:    classifyGap(gap, med, mad, k),
+			ZScore:   zScore(gap, med, mad),
+		}
+		if entry.Class == AnomalyLong && impute {
+			entry.Imputed = true
+			entry.ImputedBoundaries = imputeBoundaries(dates[i], dates[i+1], gap, med)
+		}
+		entries = append(entries, entry)
+	}
+
+	return AnomalyReport{Median: med, MAD: mad, K: k, Entries: entries}
+}
+
+// classifyGap 依据稳健中心/离散度将一次间隔分类
+func classifyGap(gap int, med, mad, k float64) AnomalyClass {
+	g := float64(gap)
+	if g > 2*med {
+		return AnomalyExtreme
+	}
+	if mad == 0 {
+		return AnomalyNormal
+	}
+	if g > med+k*mad {
+		return AnomalyLong
+	}
+	if g < med-k*mad {
+		return AnomalyShort
+	}
+	return AnomalyNormal
+}
+
+// zScore 计算间隔相对中位数/MAD 的 z-score，MAD 为 0 时返回 0
+func zScore(gap int, med, mad float64) float64 {
+	if mad == 0 {
+		return 0
+	}
+	return (float64(gap) - med) / mad
+}
+
+// imputeBoundaries 在一次过长的间隔中按 prev + round(gap/median) 等分插入合成周期边界
+func imputeBoundaries(prev, next time.Time, gap int, med float64) []time.Time {
+	n := int(math.Round(float64(gap) / med))
+	if n < 2 {
+		n = 2
+	}
+	interval := float64(gap) / float64(n)
+	boundaries := make([]time.Time, 0, n-1)
+	for i := 1; i < n; i++ {
+		boundaries = append(boundaries, prev.AddDate(0, 0, int(math.Round(interval*float64(i)))))
+	}
+	return boundaries
+}
+
+// effectiveCycleLengths 将异常报告转换为用于统计/预测的周期长度序列：
+// extreme 视为漏记，不计入统计；long 在启用 --impute 时拆分为多段合成周期；其余按原始间隔计入
+func effectiveCycleLengths(report AnomalyReport) []int {
+	var lengths []int
+	for _, e := range report.Entries {
+		switch {
+		case e.Class == AnomalyExtreme:
+			continue
+		case e.Class == AnomalyLong && e.Imputed:
+			prev := e.PrevDate
+			for _, b := range e.ImputedBoundaries {
+				lengths = append(lengths, daysBetween(prev, b))
+				prev = b
+			}
+			lengths = append(lengths, daysBetween(prev, e.NextDate))
+		default:
+			lengths = append(lengths, e.Gap)
+		}
+	}
+	return lengths
+}
+
+// printAnomalyReport 以文本表格或 JSON（-o json）形式输出异常检测报告
+func printAnomalyReport(report AnomalyReport, format string) {
+	if format == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Printf("序列化异常报告时出错: %v\n", err)
+			return
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	fmt.Printf("异常检测：中位数 %.2f 天，MAD %.2f 天\n", report.Median, report.MAD)
+	for _, e := range report.Entries {
+		fmt.Printf("  #%d %s -> %s：%d 天，分类 %s，z-score %.2f",
+			e.Index, e.PrevDate.Format("2006-01-02"), e.NextDate.Format("2006-01-02"), e.Gap, e.Class, e.ZScore)
+		if e.Imputed {
+			fmt.Printf("，已插入 %d 个合成边界", len(e.ImputedBoundaries))
+		}
+		fmt.Println()
+	}
+}
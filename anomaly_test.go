@@ -0,0 +1,106 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClassifyGap(t *testing.T) {
+	cases := []struct {
+		name string
+		gap  int
+		med  float64
+		mad  float64
+		k    float64
+		want AnomalyClass
+	}{
+		{"normal", 28, 28, 1, 1.5, AnomalyNormal},
+		{"short", 20, 28, 1, 1.5, AnomalyShort},
+		{"long", 35, 28, 1, 1.5, AnomalyLong},
+		{"extreme", 60, 28, 1, 1.5, AnomalyExtreme},
+		{"mad为0时不判定short/long", 40, 28, 0, 1.5, AnomalyNormal},
+	}
+	for _, c := range cases {
+		if got := classifyGap(c.gap, c.med, c.mad, c.k); got != c.want {
+			t.Errorf("%s: classifyGap(%d, %.0f, %.0f, %.1f) = %v, want %v", c.name, c.gap, c.med, c.mad, c.k, got, c.want)
+		}
+	}
+}
+
+func TestZScore(t *testing.T) {
+	if got := zScore(30, 28, 2); got != 1 {
+		t.Errorf("zScore = %v, want 1", got)
+	}
+	if got := zScore(30, 28, 0); got != 0 {
+		t.Errorf("MAD 为 0 时 zScore 应返回 0，got %v", got)
+	}
+}
+
+func TestImputeBoundaries(t *testing.T) {
+	prev := mustDate(t, "2024-01-01")
+	next := mustDate(t, "2024-03-26") // 间隔 85 天，中位数 28 天 -> 约等分为 3 段
+	boundaries := imputeBoundaries(prev, next, 85, 28)
+	if len(boundaries) != 2 {
+		t.Fatalf("boundaries 数量 = %d, want 2", len(boundaries))
+	}
+	for _, b := range boundaries {
+		if !b.After(prev) || !b.Before(next) {
+			t.Errorf("合成边界 %v 应落在 (%v, %v) 之间", b, prev, next)
+		}
+	}
+}
+
+func TestBuildAnomalyReportClassifiesExtremeGap(t *testing.T) {
+	dates := datesForAnomalyTest(t, "2024-01-01", "2024-01-29", "2024-02-26", "2024-05-26")
+	report := buildAnomalyReport(dates, 1.5, false)
+	if len(report.Entries) != 3 {
+		t.Fatalf("Entries 数量 = %d, want 3", len(report.Entries))
+	}
+	last := report.Entries[len(report.Entries)-1]
+	if last.Class != AnomalyExtreme {
+		t.Errorf("末尾 90 天间隔应分类为 extreme，got %v", last.Class)
+	}
+}
+
+func TestBuildAnomalyReportImputesLongGap(t *testing.T) {
+	// 间隔 26/30/28/38 天：median=29，MAD=2，38 超过 median+1.5*MAD 但未到 2*median，应分类为 long
+	dates := datesForAnomalyTest(t, "2024-01-01", "2024-01-27", "2024-02-26", "2024-03-25", "2024-05-02")
+	report := buildAnomalyReport(dates, 1.5, true)
+	last := report.Entries[len(report.Entries)-1]
+	if last.Class != AnomalyLong {
+		t.Fatalf("末尾间隔应分类为 long，got %v", last.Class)
+	}
+	if !last.Imputed || len(last.ImputedBoundaries) == 0 {
+		t.Error("启用 --impute 时 long 间隔应插入合成边界")
+	}
+}
+
+func TestEffectiveCycleLengthsDropsExtremeAndSplitsImputed(t *testing.T) {
+	// 间隔 28/28/28/29/40/122 天：median=28.5，MAD=0.5，40 天为 long，122 天为 extreme
+	dates := datesForAnomalyTest(t, "2024-01-01", "2024-01-29", "2024-02-26", "2024-03-25", "2024-04-23", "2024-06-02", "2024-10-02")
+	report := buildAnomalyReport(dates, 1.5, true)
+	lengths := effectiveCycleLengths(report)
+
+	total := 0
+	for _, l := range lengths {
+		total += l
+	}
+	// 最后一段（2024-06-02 -> 2024-10-02，122天）应被判定为 extreme 并整体剔除，
+	// 40 天的 long 间隔被拆分为两段合成周期，其余间隔按原值计入
+	if total >= daysBetween(mustDate(t, "2024-01-01"), mustDate(t, "2024-10-02")) {
+		t.Errorf("extreme 间隔未被剔除：lengths = %v", lengths)
+	}
+	if len(lengths) != 6 {
+		t.Errorf("long 间隔应被拆分为两段：lengths = %v, want 6 段", lengths)
+	}
+}
+
+// datesForAnomalyTest 是 mustDate 的便捷包装，用于按顺序构造测试用的日期序列
+func datesForAnomalyTest(t *testing.T, strs ...string) []time.Time {
+	t.Helper()
+	dates := make([]time.Time, len(strs))
+	for i, s := range strs {
+		dates[i] = mustDate(t, s)
+	}
+	return dates
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTempCSV(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "records.csv")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("写入临时 CSV 失败: %v", err)
+	}
+	return path
+}
+
+func TestReadRecordsFromCSVMixedWidthRows(t *testing.T) {
+	// 3 列、5 列、7 列混合：BBT/LH/经量/症状都是可选列
+	path := writeTempCSV(t, "2024,1,1\n2024,1,29,36.8,false\n2024,2,26,37.1,true,2,cramps;spotting\n")
+
+	records, err := readRecordsFromCSV(path)
+	if err != nil {
+		t.Fatalf("意外的 error: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("records 数量 = %d, want 3", len(records))
+	}
+
+	last := records[2]
+	if last.Flow != 2 {
+		t.Errorf("Flow = %d, want 2", last.Flow)
+	}
+	if len(last.Symptoms) != 2 || last.Symptoms[0] != "cramps" || last.Symptoms[1] != "spotting" {
+		t.Errorf("Symptoms = %v, want [cramps spotting]", last.Symptoms)
+	}
+	if !last.LH || last.BBT != 37.1 {
+		t.Errorf("BBT/LH = %v/%v, want 37.1/true", last.BBT, last.LH)
+	}
+}
+
+func TestReadRecordsFromCSVSkipsUnparsableRows(t *testing.T) {
+	// readRecordsFromCSV 只负责类型转换，月份/日期范围校验留给 validDate，
+	// 因此除列数不在 3-7 之间的行外，只有年/月/日无法转换成数字的行会在这里被跳过
+	path := writeTempCSV(t, "notayear,1,1\n2024,1,1\n")
+
+	records, err := readRecordsFromCSV(path)
+	if err != nil {
+		t.Fatalf("意外的 error: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("records 数量 = %d, want 1", len(records))
+	}
+}
@@ -0,0 +1,125 @@
+// Package cyclestats 提供按周期滚动/累计视角观察月经记录的统计能力，
+// 用于在单点预测之外展示趋势（滚动均值/标准差、周期漂移提醒）。
+package cyclestats
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// DefaultDriftThreshold 是滚动均值与全程均值之间触发"周期漂移"提醒的默认阈值（天）
+const DefaultDriftThreshold = 3.0
+
+// CycleEntry 表示报告中的一行：某一次月经发生时的周期长度及其滚动统计
+type CycleEntry struct {
+	CycleIndex      int       // 周期序号，从 1 开始
+	Date            time.Time // 本次月经日期
+	Length          int       // 距上一次月经的天数
+	RollingMean     float64   // 最近 window 个周期长度的均值
+	RollingMin      int       // 最近 window 个周期长度的最小值
+	RollingMax      int       // 最近 window 个周期长度的最大值
+	RollingStd      float64   // 最近 window 个周期长度的标准差
+	CumulativeCount int       // 截至本次为止记录到的月经次数（累计计数）
+	DriftWarning    bool      // 滚动均值与全程均值的偏差是否超过阈值
+}
+
+// CumulativeReport 是累计/滚动统计报告
+type CumulativeReport struct {
+	Entries        []CycleEntry
+	AllTimeMean    float64
+	Window         int
+	DriftThreshold float64
+}
+
+// BuildCumulativeReport 基于已排序的月经日期生成累计/滚动统计报告
+// window 为滚动窗口大小（最近 N 个周期），driftThreshold<=0 时使用 DefaultDriftThreshold
+func BuildCumulativeReport(dates []time.Time, window int, driftThreshold float64) (CumulativeReport, error) {
+	if len(dates) < 2 {
+		return CumulativeReport{}, fmt.Errorf("月经记录不足，至少需要 2 条才能计算周期统计")
+	}
+	if window <= 0 {
+		return CumulativeReport{}, fmt.Errorf("滚动窗口大小必须为正数，got %d", window)
+	}
+	if driftThreshold <= 0 {
+		driftThreshold = DefaultDriftThreshold
+	}
+
+	lengths := make([]int, 0, len(dates)-1)
+	for i := 1; i < len(dates); i++ {
+		lengths = append(lengths, daysBetween(dates[i-1], dates[i]))
+	}
+	allTimeMean := mean(lengths)
+
+	entries := make([]CycleEntry, 0, len(lengths))
+	for i, length := range lengths {
+		start := i - window + 1
+		if start < 0 {
+			start = 0
+		}
+		windowLengths := lengths[start : i+1]
+		rollingMean := mean(windowLengths)
+		rollingStd := stdDev(windowLengths, rollingMean)
+		rollingMin, rollingMax := minMax(windowLengths)
+
+		entries = append(entries, CycleEntry{
+			CycleIndex:      i + 1,
+			Date:            dates[i+1],
+			Length:          length,
+			RollingMean:     rollingMean,
+			RollingMin:      rollingMin,
+			RollingMax:      rollingMax,
+			RollingStd:      rollingStd,
+			CumulativeCount: i + 2, // 本次之前已有 1 次记录，加上本次
+			DriftWarning:    math.Abs(rollingMean-allTimeMean) > driftThreshold,
+		})
+	}
+
+	return CumulativeReport{
+		Entries:        entries,
+		AllTimeMean:    allTimeMean,
+		Window:         window,
+		DriftThreshold: driftThreshold,
+	}, nil
+}
+
+func mean(values []int) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return float64(total) / float64(len(values))
+}
+
+func stdDev(values []int, m float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sumSq float64
+	for _, v := range values {
+		d := float64(v) - m
+		sumSq += d * d
+	}
+	return math.Sqrt(sumSq / float64(len(values)))
+}
+
+func daysBetween(start, end time.Time) int {
+	return int(end.Sub(start).Hours() / 24)
+}
+
+// minMax 返回切片中的最小值和最大值，切片不为空（调用方保证）
+func minMax(values []int) (min, max int) {
+	min, max = values[0], values[0]
+	for _, v := range values[1:] {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+	return
+}
@@ -0,0 +1,83 @@
+package cyclestats
+
+import (
+	"testing"
+	"time"
+)
+
+func mustDate(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		t.Fatalf("解析日期失败: %v", err)
+	}
+	return d
+}
+
+func datesFrom(t *testing.T, strs ...string) []time.Time {
+	t.Helper()
+	dates := make([]time.Time, len(strs))
+	for i, s := range strs {
+		dates[i] = mustDate(t, s)
+	}
+	return dates
+}
+
+func TestBuildCumulativeReportInsufficientDates(t *testing.T) {
+	if _, err := BuildCumulativeReport(datesFrom(t, "2024-01-01"), 3, 0); err == nil {
+		t.Error("少于 2 条日期应返回 error")
+	}
+}
+
+func TestBuildCumulativeReportInvalidWindow(t *testing.T) {
+	dates := datesFrom(t, "2024-01-01", "2024-01-29")
+	if _, err := BuildCumulativeReport(dates, 0, 0); err == nil {
+		t.Error("window<=0 应返回 error")
+	}
+}
+
+func TestBuildCumulativeReportBasic(t *testing.T) {
+	dates := datesFrom(t, "2024-01-01", "2024-01-29", "2024-02-26", "2024-03-25")
+	report, err := BuildCumulativeReport(dates, 2, 0)
+	if err != nil {
+		t.Fatalf("意外的 error: %v", err)
+	}
+	if len(report.Entries) != 3 {
+		t.Fatalf("Entries 数量 = %d, want 3", len(report.Entries))
+	}
+	if report.AllTimeMean != 28 {
+		t.Errorf("AllTimeMean = %v, want 28", report.AllTimeMean)
+	}
+
+	last := report.Entries[len(report.Entries)-1]
+	if last.CumulativeCount != 4 {
+		t.Errorf("最后一条 CumulativeCount = %d, want 4", last.CumulativeCount)
+	}
+	if last.RollingMin != 28 || last.RollingMax != 28 {
+		t.Errorf("RollingMin/RollingMax = %d/%d, want 28/28", last.RollingMin, last.RollingMax)
+	}
+}
+
+func TestBuildCumulativeReportDriftWarning(t *testing.T) {
+	// 前两个周期 28 天，最后一个骤增到 40 天，应触发漂移提醒
+	dates := datesFrom(t, "2024-01-01", "2024-01-29", "2024-02-26", "2024-04-06")
+	report, err := BuildCumulativeReport(dates, 2, 1)
+	if err != nil {
+		t.Fatalf("意外的 error: %v", err)
+	}
+	last := report.Entries[len(report.Entries)-1]
+	if !last.DriftWarning {
+		t.Error("周期长度骤增后应标记 DriftWarning = true")
+	}
+}
+
+func TestBuildCumulativeReportUsesDefaultDriftThreshold(t *testing.T) {
+	dates := datesFrom(t, "2024-01-01", "2024-01-29", "2024-02-26")
+	report, err := BuildCumulativeReport(dates, 2, 0)
+	if err != nil {
+		t.Fatalf("意外的 error: %v", err)
+	}
+	if report.DriftThreshold != DefaultDriftThreshold {
+		t.Errorf("DriftThreshold = %v, want %v", report.DriftThreshold, DefaultDriftThreshold)
+	}
+}
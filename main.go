@@ -7,20 +7,43 @@ import (
 	"os"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/nuominmin/menstrual-prediction/cyclestats"
 )
 
-var recordPath string // 记录文件路径
-var delayDays int     // 容忍的延迟天数
-var tolerance int     // 容忍的周期波动范围
+var recordPath string    // 记录文件路径
+var delayDays int        // 容忍的延迟天数（不参与预测计算，预测区间由所选策略的离散度决定）
+var predictorName string // 预测策略名称
+var ewmaAlpha float64    // EWMA 平滑系数
+var reportMode string    // 输出报告模式: "" 为默认单点预测, "rolling" 为累计/滚动报告
+var rollingWindow int    // 滚动统计窗口大小（最近 N 个周期）
+var anomalyK float64     // 异常检测的 MAD 倍数阈值
+var imputeAnomalies bool // 是否对过长间隔插入合成周期边界
+var outputFormat string  // 异常报告输出格式: text|json
 
 func init() {
 	flag.StringVar(&recordPath, "r", "./menstruation_records.csv", "导入的 csv 记录文件路径, eg: -r ./menstruation_records.csv")
 	flag.IntVar(&delayDays, "d", 5, "容忍的月经延迟天数, e.g., -d 5")
-	flag.IntVar(&tolerance, "t", 15, "周期波动容忍范围（如超过该范围的周期将被视为异常）, e.g., -t 15")
+	flag.StringVar(&predictorName, "m", "mean", "预测策略: mean|median|wma|ewma|linear, e.g., -m ewma")
+	flag.Float64Var(&ewmaAlpha, "alpha", 0.3, "ewma 策略的平滑系数 (0, 1], e.g., -alpha 0.3")
+	flag.StringVar(&reportMode, "report", "", "报告模式: rolling 输出累计/滚动统计表, 留空则输出单点预测, e.g., --report rolling")
+	flag.IntVar(&rollingWindow, "w", 6, "滚动统计窗口大小（最近 N 个周期）, e.g., -w 6")
+	flag.Float64Var(&anomalyK, "k", 1.5, "异常检测的 MAD 倍数阈值，超出 median±k*MAD 视为 short/long, e.g., -k 1.5")
+	flag.BoolVar(&imputeAnomalies, "impute", false, "对 long 间隔按 prev+round(gap/median) 等分插入合成周期边界, e.g., --impute")
+	flag.StringVar(&outputFormat, "o", "text", "异常报告输出格式: text|json, e.g., -o json")
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		if err := runServe(os.Args[2:]); err != nil {
+			fmt.Printf("启动服务时出错: %v\n", err)
+		}
+		return
+	}
+	flag.Parse()
+
 	records, err := readRecordsFromCSV(recordPath)
 	if err != nil {
 		fmt.Printf("读取CSV文件时出错: %v\n", err)
@@ -33,7 +56,24 @@ func main() {
 	}
 
 	dates := parseAndSortDates(records)
-	cycleLengths, averageCycle, minCycle, maxCycle := calculateCycleStats(dates)
+
+	if len(dates) < 2 {
+		fmt.Println("没有足够的有效月经记录来计算周期长度。")
+		return
+	}
+
+	if reportMode == "rolling" {
+		printRollingReport(dates)
+		return
+	}
+
+	anomalies := buildAnomalyReport(dates, anomalyK, imputeAnomalies)
+	printAnomalyReport(anomalies, outputFormat)
+
+	cycleLengths, averageCycle, minCycle, maxCycle := calculateCycleStats(effectiveCycleLengths(anomalies))
+	if cycleLengths == nil {
+		return
+	}
 
 	fmt.Printf("计算出的周期长度（天）：%v\n", cycleLengths)
 	fmt.Printf("平均周期长度：%.2f 天\n", averageCycle)
@@ -43,22 +83,77 @@ func main() {
 	lastDate := dates[len(dates)-1]
 	fmt.Printf("最后一次月经日期：%s\n", lastDate.Format("2006-01-02"))
 
-	// 预测下一次月经日期范围
-	predictedEarliest := lastDate.AddDate(0, 0, int(averageCycle)-delayDays)
-	predictedLatest := lastDate.AddDate(0, 0, int(averageCycle)+delayDays)
-	fmt.Printf("预测的下一次月经日期范围：%s 至 %s\n", predictedEarliest.Format("2006-01-02"), predictedLatest.Format("2006-01-02"))
+	predictor, err := newPredictor(predictorName, ewmaAlpha)
+	if err != nil {
+		fmt.Printf("选择预测策略时出错: %v\n", err)
+		return
+	}
+
+	result, err := predictor.Predict(cycleLengths, lastDate)
+	if err != nil {
+		fmt.Printf("预测下一次月经日期时出错: %v\n", err)
+		return
+	}
+
+	fmt.Printf("预测策略：%s\n", predictor.Name())
+	fmt.Printf("预测的下一次月经日期：%s\n", result.PredictedDate.Format("2006-01-02"))
+	fmt.Printf("预测的下一次月经日期范围：%s 至 %s\n", result.Earliest.Format("2006-01-02"), result.Latest.Format("2006-01-02"))
+	fmt.Printf("置信度：%.2f\n", result.Confidence)
+
+	phase := EstimatePhaseModel(cycleLengths)
+	ovulation, fertileStart, fertileEnd := fertileWindow(result.PredictedDate, phase)
+	fmt.Printf("估计黄体期长度：%.1f 天（默认经验值）\n", phase.LutealLength)
+	fmt.Printf("估计卵泡期长度：%.1f 天\n", phase.FollicularLength)
+	fmt.Printf("预测排卵日：%s\n", ovulation.Format("2006-01-02"))
+	fmt.Printf("预测易孕窗口：%s 至 %s\n", fertileStart.Format("2006-01-02"), fertileEnd.Format("2006-01-02"))
+}
+
+// printRollingReport 输出累计/滚动统计表（--report rolling 模式）
+func printRollingReport(dates []time.Time) {
+	report, err := cyclestats.BuildCumulativeReport(dates, rollingWindow, cyclestats.DefaultDriftThreshold)
+	if err != nil {
+		fmt.Printf("生成滚动统计报告时出错: %v\n", err)
+		return
+	}
+
+	fmt.Printf("全程平均周期长度：%.2f 天（滚动窗口：%d）\n", report.AllTimeMean, report.Window)
+	fmt.Printf("%-10s %-12s %-6s %-12s %-10s %-10s %-10s %-16s\n",
+		"cycle_index", "date", "length", "rolling_mean", "rolling_min", "rolling_max", "rolling_std", "cumulative_count")
+	for _, e := range report.Entries {
+		fmt.Printf("%-10d %-12s %-6d %-12.2f %-10d %-10d %-10.2f %-16d\n",
+			e.CycleIndex, e.Date.Format("2006-01-02"), e.Length, e.RollingMean, e.RollingMin, e.RollingMax, e.RollingStd, e.CumulativeCount)
+		if e.DriftWarning {
+			fmt.Printf("  警告：周期长度可能正在漂移（滚动均值 %.2f 天偏离全程均值 %.2f 天超过 %.2f 天）\n",
+				e.RollingMean, report.AllTimeMean, report.DriftThreshold)
+		}
+	}
 }
 
 // 解析并排序日期
 func parseAndSortDates(records []MenstruationRecord) []time.Time {
-	var dates []time.Time
+	valid := sortedValidRecords(records)
+	dates := make([]time.Time, len(valid))
+	for i, rec := range valid {
+		dates[i] = recordDate(rec)
+	}
+	return dates
+}
+
+// sortedValidRecords 过滤掉日期无效的记录并按日期升序排序，供需要保留 Flow/BBT 等字段的场景使用
+func sortedValidRecords(records []MenstruationRecord) []MenstruationRecord {
+	var valid []MenstruationRecord
 	for _, rec := range records {
 		if validDate(rec) {
-			dates = append(dates, time.Date(rec.Year, time.Month(rec.Month), rec.Day, 0, 0, 0, 0, time.UTC))
+			valid = append(valid, rec)
 		}
 	}
-	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
-	return dates
+	sort.Slice(valid, func(i, j int) bool { return recordDate(valid[i]).Before(recordDate(valid[j])) })
+	return valid
+}
+
+// recordDate 将记录的年月日转换成 time.Time
+func recordDate(rec MenstruationRecord) time.Time {
+	return time.Date(rec.Year, time.Month(rec.Month), rec.Day, 0, 0, 0, 0, time.UTC)
 }
 
 // 校验日期有效性
@@ -66,23 +161,19 @@ func validDate(rec MenstruationRecord) bool {
 	return rec.Month >= 1 && rec.Month <= 12 && rec.Day >= 1 && rec.Day <= 31
 }
 
-// 计算周期统计数据
-func calculateCycleStats(dates []time.Time) ([]int, float64, int, int) {
-	var cycleLengths []int
-	total := 0
-	for i := 1; i < len(dates); i++ {
-		days := daysBetween(dates[i-1], dates[i])
-		if days >= tolerance && days <= 35 { // 过滤掉异常值
-			cycleLengths = append(cycleLengths, days)
-			total += days
-		}
-	}
-
+// 计算周期统计数据。cycleLengths 预期已经过 buildAnomalyReport/effectiveCycleLengths 处理，
+// 异常间隔的剔除/插补在那一步完成，这里只负责汇总
+func calculateCycleStats(cycleLengths []int) ([]int, float64, int, int) {
 	if len(cycleLengths) == 0 {
 		fmt.Println("没有足够的有效月经周期记录来计算统计数据。")
 		return nil, 0, 0, 0
 	}
 
+	total := 0
+	for _, days := range cycleLengths {
+		total += days
+	}
+
 	averageCycle := float64(total) / float64(len(cycleLengths))
 	minCycle, maxCycle := minMax(cycleLengths)
 	return cycleLengths, averageCycle, minCycle, maxCycle
@@ -102,11 +193,18 @@ func minMax(cycles []int) (min, max int) {
 	return
 }
 
-// MenstruationRecord 结构体用于存储每次月经的年份、月份和日期
+// MenstruationRecord 结构体用于存储每次月经的年份、月份和日期，
+// 并可选携带基础体温/排卵试纸读数，以及经量、症状标签
 type MenstruationRecord struct {
 	Year  int
 	Month int
 	Day   int
+
+	BBT float64 // 基础体温（摄氏度），CSV 第 4 列，0 表示未记录
+	LH  bool    // 排卵试纸是否呈阳性，CSV 第 5 列，未记录时为 false
+
+	Flow     int      // 经量等级 0-4，CSV 第 6 列，0 表示未记录
+	Symptoms []string // 症状标签（如 "cramps"、"spotting"），CSV 第 7 列用 ";" 分隔，未记录时为空
 }
 
 // 计算两日期之间的天数差
@@ -123,6 +221,7 @@ func readRecordsFromCSV(filePath string) ([]MenstruationRecord, error) {
 	defer file.Close()
 
 	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1 // BBT/LH/经量/症状都是可选列，行宽允许不一致
 	var records [][]string
 	if records, err = reader.ReadAll(); err != nil {
 		return nil, fmt.Errorf("failed to read records: %v", err)
@@ -130,7 +229,8 @@ func readRecordsFromCSV(filePath string) ([]MenstruationRecord, error) {
 
 	var menstruationRecords []MenstruationRecord
 	for i := 0; i < len(records); i++ {
-		if len(records[i]) != 3 {
+		// 前 3 列（年/月/日）必填，第 4-7 列（BBT/排卵试纸/经量/症状）可选
+		if len(records[i]) < 3 || len(records[i]) > 7 {
 			continue // 跳过无效记录
 		}
 		year, err1 := strconv.Atoi(records[i][0])
@@ -139,11 +239,28 @@ func readRecordsFromCSV(filePath string) ([]MenstruationRecord, error) {
 		if err1 != nil || err2 != nil || err3 != nil {
 			continue // 跳过转换错误的记录
 		}
-		menstruationRecords = append(menstruationRecords, MenstruationRecord{
-			Year:  year,
-			Month: month,
-			Day:   day,
-		})
+
+		rec := MenstruationRecord{Year: year, Month: month, Day: day}
+		if len(records[i]) >= 4 && records[i][3] != "" {
+			if bbt, err := strconv.ParseFloat(records[i][3], 64); err == nil {
+				rec.BBT = bbt
+			}
+		}
+		if len(records[i]) >= 5 && records[i][4] != "" {
+			if lh, err := strconv.ParseBool(records[i][4]); err == nil {
+				rec.LH = lh
+			}
+		}
+		if len(records[i]) >= 6 && records[i][5] != "" {
+			if flow, err := strconv.Atoi(records[i][5]); err == nil {
+				rec.Flow = flow
+			}
+		}
+		if len(records[i]) == 7 && records[i][6] != "" {
+			rec.Symptoms = strings.Split(records[i][6], ";")
+		}
+
+		menstruationRecords = append(menstruationRecords, rec)
 	}
 
 	return menstruationRecords, nil
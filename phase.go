@@ -0,0 +1,44 @@
+package main
+
+import "time"
+
+// PhaseModel 描述从周期数据估计出的卵泡期/黄体期长度，用于在经期窗口之外推算排卵日和易孕窗口
+type PhaseModel struct {
+	LutealLength     float64 // 估计的黄体期长度（天），假定对同一用户相对恒定，取经验默认值
+	FollicularLength float64 // 估计的卵泡期长度（天）= 平均周期长度 - 黄体期长度，逐周期会变化，这里取平均值
+}
+
+// DefaultLutealLength 是经验黄体期长度（典型范围 12-14 天）。
+//
+// 注意：现有 CSV 每次月经只记录一个 BBT 读数，而不是逐日体温曲线，无法在单次周期内
+// 定位"体温位移"发生的具体日期，因此这里不尝试用 BBT 反推黄体期长度，只使用经验默认值；
+// 一旦 CSV 支持逐日体温记录，可以在此基础上按当前周期的位移日期重新估计。
+const DefaultLutealLength = 14.0
+
+// EstimatePhaseModel 用经验默认的黄体期长度，结合 cycleLengths（经过异常检测/插补后的
+// 有效周期长度）反推卵泡期长度
+func EstimatePhaseModel(cycleLengths []int) PhaseModel {
+	phase := PhaseModel{LutealLength: DefaultLutealLength}
+	phase.FollicularLength = estimateFollicularLength(cycleLengths, phase.LutealLength)
+	return phase
+}
+
+// estimateFollicularLength 用每个周期长度减去（假定恒定的）黄体期长度，再取平均作为卵泡期估计
+func estimateFollicularLength(cycleLengths []int, lutealLength float64) float64 {
+	if len(cycleLengths) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, c := range cycleLengths {
+		total += float64(c) - lutealLength
+	}
+	return total / float64(len(cycleLengths))
+}
+
+// fertileWindow 由预测的下一次月经开始日期与黄体期长度推算排卵日和易孕窗口（排卵日 -5 ~ +1）
+func fertileWindow(predictedStart time.Time, phase PhaseModel) (ovulation, start, end time.Time) {
+	ovulation = predictedStart.AddDate(0, 0, -int(phase.LutealLength))
+	start = ovulation.AddDate(0, 0, -5)
+	end = ovulation.AddDate(0, 0, 1)
+	return
+}
@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func TestEstimatePhaseModelUsesDefaultLutealLength(t *testing.T) {
+	phase := EstimatePhaseModel([]int{28, 30, 26})
+	if phase.LutealLength != DefaultLutealLength {
+		t.Errorf("LutealLength = %v, want %v", phase.LutealLength, DefaultLutealLength)
+	}
+	want := (28.0 + 30.0 + 26.0) / 3
+	if got := phase.FollicularLength + phase.LutealLength; got != want {
+		t.Errorf("FollicularLength+LutealLength = %v, want %v", got, want)
+	}
+}
+
+func TestEstimatePhaseModelEmptyCycleLengths(t *testing.T) {
+	phase := EstimatePhaseModel(nil)
+	if phase.FollicularLength != 0 {
+		t.Errorf("空 cycleLengths 时 FollicularLength = %v, want 0", phase.FollicularLength)
+	}
+}
+
+func TestFertileWindow(t *testing.T) {
+	predictedStart := mustDate(t, "2024-03-01")
+	phase := PhaseModel{LutealLength: 14}
+
+	ovulation, start, end := fertileWindow(predictedStart, phase)
+
+	wantOvulation := predictedStart.AddDate(0, 0, -14)
+	if !ovulation.Equal(wantOvulation) {
+		t.Errorf("ovulation = %v, want %v", ovulation, wantOvulation)
+	}
+	if !start.Equal(wantOvulation.AddDate(0, 0, -5)) {
+		t.Errorf("start = %v, want ovulation-5", start)
+	}
+	if !end.Equal(wantOvulation.AddDate(0, 0, 1)) {
+		t.Errorf("end = %v, want ovulation+1", end)
+	}
+}
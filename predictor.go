@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// PredictionResult 表示某种预测策略给出的下一次月经预测结果
+type PredictionResult struct {
+	PredictedDate time.Time // 预测的下一次月经日期
+	Earliest      time.Time // 预测区间下界
+	Latest        time.Time // 预测区间上界
+	Confidence    float64   // 置信度，取值范围 [0, 1]，越大表示离散度越小
+}
+
+// Predictor 是周期预测策略的统一接口，不同算法通过实现该接口接入 CLI 的 -m 参数
+type Predictor interface {
+	// Name 返回策略名称，用于 -m 参数匹配与输出展示
+	Name() string
+	// Predict 基于历史周期长度和最后一次月经日期给出预测结果
+	Predict(cycleLengths []int, lastDate time.Time) (PredictionResult, error)
+}
+
+// newPredictor 根据名称构造对应的预测策略，未匹配到时返回 error
+func newPredictor(name string, alpha float64) (Predictor, error) {
+	switch name {
+	case "", "mean":
+		return meanPredictor{}, nil
+	case "median":
+		return medianPredictor{}, nil
+	case "wma":
+		return wmaPredictor{}, nil
+	case "ewma":
+		return ewmaPredictor{alpha: alpha}, nil
+	case "linear":
+		return linearPredictor{}, nil
+	default:
+		return nil, fmt.Errorf("未知的预测策略: %s", name)
+	}
+}
+
+// confidenceFromSpread 将离散度换算成 [0, 1] 的置信度，离散度越小置信度越高
+func confidenceFromSpread(center, spread float64) float64 {
+	if center <= 0 {
+		return 0
+	}
+	c := 1 / (1 + spread/center)
+	if c < 0 {
+		return 0
+	}
+	if c > 1 {
+		return 1
+	}
+	return c
+}
+
+// stdDev 计算总体标准差（平方和除以 n）
+func stdDev(values []int, mean float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sumSq float64
+	for _, v := range values {
+		d := float64(v) - mean
+		sumSq += d * d
+	}
+	return math.Sqrt(sumSq / float64(len(values)))
+}
+
+// meanPredictor 沿用最初的算法：下一次日期 = 最后一次日期 + 平均周期长度，离散度用标准差估计
+type meanPredictor struct{}
+
+func (meanPredictor) Name() string { return "mean" }
+
+func (meanPredictor) Predict(cycleLengths []int, lastDate time.Time) (PredictionResult, error) {
+	if len(cycleLengths) == 0 {
+		return PredictionResult{}, fmt.Errorf("没有可用的周期长度数据")
+	}
+	total := 0
+	for _, v := range cycleLengths {
+		total += v
+	}
+	mean := float64(total) / float64(len(cycleLengths))
+	sd := stdDev(cycleLengths, mean)
+	return buildResult(lastDate, mean, sd), nil
+}
+
+// medianPredictor 使用中位数预测，对异常长/短的周期更鲁棒，离散度用 MAD（中位绝对偏差）估计
+type medianPredictor struct{}
+
+func (medianPredictor) Name() string { return "median" }
+
+func (medianPredictor) Predict(cycleLengths []int, lastDate time.Time) (PredictionResult, error) {
+	if len(cycleLengths) == 0 {
+		return PredictionResult{}, fmt.Errorf("没有可用的周期长度数据")
+	}
+	med := median(cycleLengths)
+	mad := medianAbsoluteDeviation(cycleLengths, med)
+	return buildResult(lastDate, med, mad), nil
+}
+
+// wmaPredictor 加权移动平均，权重 1..n 线性递增并归一化，越靠近最近的周期权重越大
+type wmaPredictor struct{}
+
+func (wmaPredictor) Name() string { return "wma" }
+
+func (wmaPredictor) Predict(cycleLengths []int, lastDate time.Time) (PredictionResult, error) {
+	n := len(cycleLengths)
+	if n == 0 {
+		return PredictionResult{}, fmt.Errorf("没有可用的周期长度数据")
+	}
+	weightSum := float64(n * (n + 1) / 2)
+	var wma float64
+	for i, v := range cycleLengths {
+		weight := float64(i+1) / weightSum
+		wma += weight * float64(v)
+	}
+	var variance float64
+	for i, v := range cycleLengths {
+		weight := float64(i+1) / weightSum
+		d := float64(v) - wma
+		variance += weight * d * d
+	}
+	return buildResult(lastDate, wma, math.Sqrt(variance)), nil
+}
+
+// ewmaPredictor 指数加权移动平均，alpha 越大越偏向最近的观测值
+type ewmaPredictor struct {
+	alpha float64
+}
+
+func (ewmaPredictor) Name() string { return "ewma" }
+
+func (p ewmaPredictor) Predict(cycleLengths []int, lastDate time.Time) (PredictionResult, error) {
+	if len(cycleLengths) == 0 {
+		return PredictionResult{}, fmt.Errorf("没有可用的周期长度数据")
+	}
+	alpha := p.alpha
+	if alpha <= 0 || alpha > 1 {
+		alpha = 0.3
+	}
+	level := float64(cycleLengths[0])
+	var sumSqResid float64
+	resids := 0
+	for _, v := range cycleLengths[1:] {
+		resid := float64(v) - level
+		sumSqResid += resid * resid
+		resids++
+		level = alpha*float64(v) + (1-alpha)*level
+	}
+	sd := 0.0
+	if resids > 0 {
+		sd = math.Sqrt(sumSqResid / float64(resids))
+	}
+	return buildResult(lastDate, level, sd), nil
+}
+
+// linearPredictor 对最近的周期长度做普通最小二乘回归，外推下一个周期长度
+type linearPredictor struct{}
+
+func (linearPredictor) Name() string { return "linear" }
+
+func (linearPredictor) Predict(cycleLengths []int, lastDate time.Time) (PredictionResult, error) {
+	n := len(cycleLengths)
+	if n == 0 {
+		return PredictionResult{}, fmt.Errorf("没有可用的周期长度数据")
+	}
+	if n == 1 {
+		return buildResult(lastDate, float64(cycleLengths[0]), 0), nil
+	}
+
+	var sumX, sumY, sumXY, sumXX float64
+	for i, v := range cycleLengths {
+		x := float64(i + 1)
+		y := float64(v)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	fn := float64(n)
+	slope := (fn*sumXY - sumX*sumY) / (fn*sumXX - sumX*sumX)
+	intercept := (sumY - slope*sumX) / fn
+
+	var sumSqResid float64
+	for i, v := range cycleLengths {
+		x := float64(i + 1)
+		pred := slope*x + intercept
+		resid := float64(v) - pred
+		sumSqResid += resid * resid
+	}
+	residualStdErr := math.Sqrt(sumSqResid / fn)
+
+	predictedLength := slope*fn + slope + intercept // 外推到第 n+1 个周期
+	return buildResult(lastDate, predictedLength, residualStdErr), nil
+}
+
+// buildResult 将预测的周期长度与离散度统一转换成日期区间和置信度
+func buildResult(lastDate time.Time, predictedLength, spread float64) PredictionResult {
+	predictedDate := lastDate.AddDate(0, 0, int(math.Round(predictedLength)))
+	spreadDays := int(math.Round(spread))
+	return PredictionResult{
+		PredictedDate: predictedDate,
+		Earliest:      predictedDate.AddDate(0, 0, -spreadDays),
+		Latest:        predictedDate.AddDate(0, 0, spreadDays),
+		Confidence:    confidenceFromSpread(predictedLength, spread),
+	}
+}
+
+// median 计算整数切片的中位数（切片会被复制后排序，不修改原切片）
+func median(values []int) float64 {
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return float64(sorted[n/2])
+	}
+	return float64(sorted[n/2-1]+sorted[n/2]) / 2
+}
+
+// medianAbsoluteDeviation 计算相对于给定中心值的中位绝对偏差（MAD）
+func medianAbsoluteDeviation(values []int, center float64) float64 {
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(float64(v) - center)
+	}
+	sort.Float64s(deviations)
+	n := len(deviations)
+	if n%2 == 1 {
+		return deviations[n/2]
+	}
+	return (deviations[n/2-1] + deviations[n/2]) / 2
+}
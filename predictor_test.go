@@ -0,0 +1,134 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func mustDate(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		t.Fatalf("解析日期失败: %v", err)
+	}
+	return d
+}
+
+func TestNewPredictor(t *testing.T) {
+	cases := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"", "mean", false},
+		{"mean", "mean", false},
+		{"median", "median", false},
+		{"wma", "wma", false},
+		{"ewma", "ewma", false},
+		{"linear", "linear", false},
+		{"unknown", "", true},
+	}
+	for _, c := range cases {
+		p, err := newPredictor(c.name, 0.3)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("newPredictor(%q) 期望返回 error，实际没有", c.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("newPredictor(%q) 返回意外 error: %v", c.name, err)
+		}
+		if p.Name() != c.want {
+			t.Errorf("newPredictor(%q).Name() = %q, want %q", c.name, p.Name(), c.want)
+		}
+	}
+}
+
+func TestMeanPredictor(t *testing.T) {
+	last := mustDate(t, "2024-01-01")
+	result, err := meanPredictor{}.Predict([]int{28, 28, 28}, last)
+	if err != nil {
+		t.Fatalf("意外的 error: %v", err)
+	}
+	want := last.AddDate(0, 0, 28)
+	if !result.PredictedDate.Equal(want) {
+		t.Errorf("PredictedDate = %v, want %v", result.PredictedDate, want)
+	}
+	if result.Earliest.After(result.PredictedDate) || result.Latest.Before(result.PredictedDate) {
+		t.Errorf("预测区间应包含预测日期: earliest=%v latest=%v predicted=%v", result.Earliest, result.Latest, result.PredictedDate)
+	}
+}
+
+func TestMeanPredictorEmptyInput(t *testing.T) {
+	if _, err := (meanPredictor{}).Predict(nil, time.Now()); err == nil {
+		t.Error("空输入应返回 error")
+	}
+}
+
+func TestMedianPredictorRobustToOutlier(t *testing.T) {
+	last := mustDate(t, "2024-01-01")
+	result, err := medianPredictor{}.Predict([]int{28, 28, 90, 28}, last)
+	if err != nil {
+		t.Fatalf("意外的 error: %v", err)
+	}
+	want := last.AddDate(0, 0, 28)
+	if !result.PredictedDate.Equal(want) {
+		t.Errorf("中位数策略应不受单个异常值影响: PredictedDate = %v, want %v", result.PredictedDate, want)
+	}
+}
+
+func TestWmaPredictorWeightsRecentMore(t *testing.T) {
+	last := mustDate(t, "2024-01-01")
+	result, err := wmaPredictor{}.Predict([]int{20, 30}, last)
+	if err != nil {
+		t.Fatalf("意外的 error: %v", err)
+	}
+	// 权重 1:2，预测值应偏向最近一次（30）而不是简单平均（25）
+	predictedLength := int(result.PredictedDate.Sub(last).Hours() / 24)
+	if predictedLength <= 25 {
+		t.Errorf("wma 预测周期长度 %d 应大于简单平均 25（更偏向最近的观测）", predictedLength)
+	}
+}
+
+func TestEwmaPredictorDefaultAlpha(t *testing.T) {
+	last := mustDate(t, "2024-01-01")
+	if _, err := (ewmaPredictor{alpha: 0}).Predict([]int{28, 28, 28}, last); err != nil {
+		t.Fatalf("alpha<=0 时应回退到默认值而不是报错: %v", err)
+	}
+}
+
+func TestLinearPredictorSingleValue(t *testing.T) {
+	last := mustDate(t, "2024-01-01")
+	result, err := linearPredictor{}.Predict([]int{28}, last)
+	if err != nil {
+		t.Fatalf("意外的 error: %v", err)
+	}
+	want := last.AddDate(0, 0, 28)
+	if !result.PredictedDate.Equal(want) {
+		t.Errorf("单样本时 linear 应直接使用该值: PredictedDate = %v, want %v", result.PredictedDate, want)
+	}
+}
+
+func TestMedian(t *testing.T) {
+	cases := []struct {
+		values []int
+		want   float64
+	}{
+		{[]int{1, 2, 3}, 2},
+		{[]int{1, 2, 3, 4}, 2.5},
+		{[]int{5}, 5},
+	}
+	for _, c := range cases {
+		if got := median(c.values); got != c.want {
+			t.Errorf("median(%v) = %v, want %v", c.values, got, c.want)
+		}
+	}
+}
+
+func TestMedianAbsoluteDeviation(t *testing.T) {
+	got := medianAbsoluteDeviation([]int{1, 2, 3, 4, 5}, 3)
+	if got != 1 {
+		t.Errorf("medianAbsoluteDeviation = %v, want 1", got)
+	}
+}
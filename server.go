@@ -0,0 +1,310 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// serveAddr 是 serve 子命令监听的地址
+var serveAddr string
+
+// runServe 启动 HTTP 服务，让其它应用（日历、智能家居）通过接口消费预测结果。
+// 复用 readRecordsFromCSV、parseAndSortDates 与 Predictor 接口，CLI 与服务端共用同一套计算逻辑。
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	fs.StringVar(&serveAddr, "addr", ":8080", "HTTP 服务监听地址, e.g., -addr :8080")
+	fs.StringVar(&recordPath, "r", recordPath, "导入的 csv 记录文件路径, e.g., -r ./menstruation_records.csv")
+	fs.IntVar(&delayDays, "d", delayDays, "容忍的月经延迟天数（不参与预测计算，预测区间由所选策略的离散度决定）")
+	fs.StringVar(&predictorName, "m", predictorName, "预测策略: mean|median|wma|ewma|linear")
+	fs.Float64Var(&ewmaAlpha, "alpha", ewmaAlpha, "ewma 策略的平滑系数 (0, 1]")
+	fs.Float64Var(&anomalyK, "k", anomalyK, "异常检测的 MAD 倍数阈值")
+	fs.BoolVar(&imputeAnomalies, "impute", imputeAnomalies, "对 long 间隔插入合成周期边界")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/predict", handlePredict)
+	mux.HandleFunc("/calendar.ics", handleCalendar)
+	mux.HandleFunc("/records", handleRecords)
+
+	fmt.Printf("预测服务已启动，监听 %s\n", serveAddr)
+	return http.ListenAndServe(serveAddr, mux)
+}
+
+// predictOutcome 是一次完整的预测计算结果，供 serve 的各个接口共用
+type predictOutcome struct {
+	lastDate      time.Time
+	cycleLengths  []int
+	averageCycle  float64
+	minCycle      int
+	maxCycle      int
+	predictor     Predictor
+	prediction    PredictionResult
+	phase         PhaseModel
+	fertileStart  time.Time
+	fertileEnd    time.Time
+	anomalyReport AnomalyReport
+}
+
+// computePrediction 读取 CSV 并跑完整条预测流水线（异常检测 -> 统计 -> 预测 -> 排卵期推算）
+func computePrediction() (predictOutcome, error) {
+	records, err := readRecordsFromCSV(recordPath)
+	if err != nil {
+		return predictOutcome{}, fmt.Errorf("读取CSV文件时出错: %w", err)
+	}
+	if len(records) < 2 {
+		return predictOutcome{}, fmt.Errorf("没有足够的月经记录来计算周期长度")
+	}
+
+	dates := parseAndSortDates(records)
+	if len(dates) < 2 {
+		return predictOutcome{}, fmt.Errorf("没有足够的有效月经记录来计算周期长度")
+	}
+
+	anomalies := buildAnomalyReport(dates, anomalyK, imputeAnomalies)
+	cycleLengths, averageCycle, minCycle, maxCycle := calculateCycleStats(effectiveCycleLengths(anomalies))
+	if cycleLengths == nil {
+		return predictOutcome{}, fmt.Errorf("没有足够的有效月经周期记录来计算统计数据")
+	}
+
+	lastDate := dates[len(dates)-1]
+	predictor, err := newPredictor(predictorName, ewmaAlpha)
+	if err != nil {
+		return predictOutcome{}, err
+	}
+	prediction, err := predictor.Predict(cycleLengths, lastDate)
+	if err != nil {
+		return predictOutcome{}, err
+	}
+
+	phase := EstimatePhaseModel(cycleLengths)
+	_, fertileStart, fertileEnd := fertileWindow(prediction.PredictedDate, phase)
+
+	return predictOutcome{
+		lastDate:      lastDate,
+		cycleLengths:  cycleLengths,
+		averageCycle:  averageCycle,
+		minCycle:      minCycle,
+		maxCycle:      maxCycle,
+		predictor:     predictor,
+		prediction:    prediction,
+		phase:         phase,
+		fertileStart:  fertileStart,
+		fertileEnd:    fertileEnd,
+		anomalyReport: anomalies,
+	}, nil
+}
+
+// predictResponse 是 GET /predict 的 JSON 响应体
+type predictResponse struct {
+	LastDate         time.Time     `json:"last_date"`
+	CycleLengths     []int         `json:"cycle_lengths"`
+	AverageCycle     float64       `json:"average_cycle"`
+	MinCycle         int           `json:"min_cycle"`
+	MaxCycle         int           `json:"max_cycle"`
+	Predictor        string        `json:"predictor"`
+	PredictedDate    time.Time     `json:"predicted_date"`
+	Earliest         time.Time     `json:"earliest"`
+	Latest           time.Time     `json:"latest"`
+	Confidence       float64       `json:"confidence"`
+	LutealLength     float64       `json:"luteal_length"`
+	FollicularLength float64       `json:"follicular_length"`
+	FertileStart     time.Time     `json:"fertile_start"`
+	FertileEnd       time.Time     `json:"fertile_end"`
+	Anomalies        AnomalyReport `json:"anomalies"`
+}
+
+// handlePredict 处理 GET /predict，返回最后一次日期、周期统计、预测窗口、排卵窗口与异常报告
+func handlePredict(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "只支持 GET", http.StatusMethodNotAllowed)
+		return
+	}
+
+	outcome, err := computePrediction()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := predictResponse{
+		LastDate:         outcome.lastDate,
+		CycleLengths:     outcome.cycleLengths,
+		AverageCycle:     outcome.averageCycle,
+		MinCycle:         outcome.minCycle,
+		MaxCycle:         outcome.maxCycle,
+		Predictor:        outcome.predictor.Name(),
+		PredictedDate:    outcome.prediction.PredictedDate,
+		Earliest:         outcome.prediction.Earliest,
+		Latest:           outcome.prediction.Latest,
+		Confidence:       outcome.prediction.Confidence,
+		LutealLength:     outcome.phase.LutealLength,
+		FollicularLength: outcome.phase.FollicularLength,
+		FertileStart:     outcome.fertileStart,
+		FertileEnd:       outcome.fertileEnd,
+		Anomalies:        outcome.anomalyReport,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// handleCalendar 处理 GET /calendar.ics?horizon=N，返回未来 N 个周期的经期窗口与排卵窗口的 iCalendar 订阅
+func handleCalendar(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "只支持 GET", http.StatusMethodNotAllowed)
+		return
+	}
+
+	horizon := 6
+	if v := r.URL.Query().Get("horizon"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, "horizon 必须为正整数", http.StatusBadRequest)
+			return
+		}
+		horizon = n
+	}
+
+	outcome, err := computePrediction()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	fmt.Fprint(w, buildICalendar(outcome, horizon))
+}
+
+// buildICalendar 从预测日期起，以平均周期长度（取整）为步长向未来滚动推算 horizon 个经期窗口和排卵窗口，生成 RFC 5545 文本
+func buildICalendar(outcome predictOutcome, horizon int) string {
+	cal := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//menstrual-prediction//CN\r\nCALSCALE:GREGORIAN\r\n"
+
+	periodLength := int(outcome.prediction.Latest.Sub(outcome.prediction.PredictedDate).Hours() / 24)
+	next := outcome.prediction.PredictedDate
+	earliest := outcome.prediction.Earliest
+	for i := 0; i < horizon; i++ {
+		cal += icalEvent(fmt.Sprintf("period-%d@menstrual-prediction", i+1), "预测经期窗口", earliest, next.AddDate(0, 0, periodLength+1))
+
+		_, fertileStart, fertileEnd := fertileWindow(next, outcome.phase)
+		cal += icalEvent(fmt.Sprintf("fertile-%d@menstrual-prediction", i+1), "预测易孕窗口", fertileStart, fertileEnd.AddDate(0, 0, 1))
+
+		cycleLen := int(outcome.averageCycle)
+		earliest = earliest.AddDate(0, 0, cycleLen)
+		next = next.AddDate(0, 0, cycleLen)
+	}
+
+	cal += "END:VCALENDAR\r\n"
+	return cal
+}
+
+// icalEvent 渲染一个全天 VEVENT，[start, end) 为起止日期
+func icalEvent(uid, summary string, start, end time.Time) string {
+	const dateFmt = "20060102"
+	return "BEGIN:VEVENT\r\n" +
+		"UID:" + uid + "\r\n" +
+		"DTSTART;VALUE=DATE:" + start.Format(dateFmt) + "\r\n" +
+		"DTEND;VALUE=DATE:" + end.Format(dateFmt) + "\r\n" +
+		"SUMMARY:" + summary + "\r\n" +
+		"END:VEVENT\r\n"
+}
+
+// newRecordRequest 是 POST /records 的请求体，BBT/LH/Flow/Symptoms 均为可选字段
+type newRecordRequest struct {
+	Year  int `json:"year"`
+	Month int `json:"month"`
+	Day   int `json:"day"`
+
+	BBT float64 `json:"bbt,omitempty"`
+	LH  bool    `json:"lh,omitempty"`
+
+	Flow     int      `json:"flow,omitempty"`
+	Symptoms []string `json:"symptoms,omitempty"`
+}
+
+// handleRecords 处理 POST /records，向 CSV 追加一条记录（flock 加锁后读-改-写，避免并发写入损坏文件）
+func handleRecords(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "只支持 POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req newRecordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("请求体解析失败: %v", err), http.StatusBadRequest)
+		return
+	}
+	rec := MenstruationRecord{
+		Year: req.Year, Month: req.Month, Day: req.Day,
+		BBT: req.BBT, LH: req.LH,
+		Flow: req.Flow, Symptoms: req.Symptoms,
+	}
+	if !validDate(rec) {
+		http.Error(w, "日期无效", http.StatusBadRequest)
+		return
+	}
+
+	if err := appendRecordToCSV(recordPath, rec); err != nil {
+		http.Error(w, fmt.Sprintf("写入记录失败: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+// appendRecordToCSV 用 flock 对 CSV 文件加独占锁后原子地读-改-写，追加一条新记录
+func appendRecordToCSV(filePath string, rec MenstruationRecord) error {
+	file, err := os.OpenFile(filePath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("打开文件失败: %w", err)
+	}
+	defer file.Close()
+
+	if err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("加锁失败: %w", err)
+	}
+	defer syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1 // BBT/LH/经量/症状都是可选列，行宽允许不一致
+	existing, err := reader.ReadAll()
+	if err != nil {
+		return fmt.Errorf("读取记录失败: %w", err)
+	}
+
+	row := []string{strconv.Itoa(rec.Year), strconv.Itoa(rec.Month), strconv.Itoa(rec.Day)}
+	hasBBTOrLH := rec.BBT > 0 || rec.LH
+	hasFlowOrSymptoms := rec.Flow > 0 || len(rec.Symptoms) > 0
+	if hasBBTOrLH || hasFlowOrSymptoms {
+		row = append(row, strconv.FormatFloat(rec.BBT, 'f', 1, 64), strconv.FormatBool(rec.LH))
+	}
+	if hasFlowOrSymptoms {
+		row = append(row, strconv.Itoa(rec.Flow), strings.Join(rec.Symptoms, ";"))
+	}
+	existing = append(existing, row)
+
+	if _, err = file.Seek(0, 0); err != nil {
+		return fmt.Errorf("定位文件失败: %w", err)
+	}
+	if err = file.Truncate(0); err != nil {
+		return fmt.Errorf("清空文件失败: %w", err)
+	}
+
+	writer := csv.NewWriter(file)
+	if err = writer.WriteAll(existing); err != nil {
+		return fmt.Errorf("写入记录失败: %w", err)
+	}
+	writer.Flush()
+	return writer.Error()
+}